@@ -0,0 +1,165 @@
+package opcua
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gopcua/opcua/ua"
+
+	"github.com/influxdata/telegraf/config"
+)
+
+// ErrCircuitOpen is returned by OpcUAClient.Connect in place of a connection
+// attempt once the endpoint's circuit breaker has tripped, so callers fail
+// fast instead of blocking until ConnectTimeout. Nodes whose breaker is open
+// are left out of the Read request instead; no ErrCircuitOpen is returned
+// for them.
+var ErrCircuitOpen = errors.New("opcua: circuit breaker open")
+
+// CircuitBreakerConfig configures the "reject fast" behavior applied per
+// endpoint or node: after FailureThreshold consecutive communication
+// failures, further attempts are rejected immediately for OpenDuration
+// before HalfOpenProbes attempts are let through to probe recovery.
+type CircuitBreakerConfig struct {
+	FailureThreshold int             `toml:"failure_threshold"`
+	OpenDuration     config.Duration `toml:"open_duration"`
+	HalfOpenProbes   int             `toml:"half_open_probes"`
+}
+
+func (c *CircuitBreakerConfig) Validate() error {
+	if c.FailureThreshold <= 0 {
+		return errors.New("failure_threshold must be greater than zero")
+	}
+	if c.OpenDuration <= 0 {
+		return errors.New("open_duration must be greater than zero")
+	}
+	if c.HalfOpenProbes <= 0 {
+		return errors.New("half_open_probes must be greater than zero")
+	}
+	return nil
+}
+
+// breakerState is the state tag value reported for a given key.
+type breakerState int
+
+const (
+	stateClosed breakerState = iota
+	stateOpen
+	stateHalfOpen
+)
+
+func (s breakerState) String() string {
+	switch s {
+	case stateOpen:
+		return "open"
+	case stateHalfOpen:
+		return "half_open"
+	default:
+		return "closed"
+	}
+}
+
+// IsBreakerTrippingStatus reports whether code is one of the communication
+// failures a CircuitBreaker counts towards FailureThreshold.
+func IsBreakerTrippingStatus(code ua.StatusCode) bool {
+	switch code {
+	case ua.StatusBadCommunicationError, ua.StatusBadSessionClosed, ua.StatusBadNodeIDUnknown:
+		return true
+	default:
+		return false
+	}
+}
+
+type breakerEntry struct {
+	state        breakerState
+	failures     int
+	openedAt     time.Time
+	halfOpenLeft int
+}
+
+// CircuitBreaker tracks, independently per key (an endpoint or NodeId
+// string), consecutive communication failures and short-circuits further
+// attempts once FailureThreshold is reached rather than letting each one
+// block until RequestTimeout. Safe for concurrent use.
+type CircuitBreaker struct {
+	cfg CircuitBreakerConfig
+
+	mu      sync.Mutex
+	entries map[string]*breakerEntry
+}
+
+func NewCircuitBreaker(cfg CircuitBreakerConfig) *CircuitBreaker {
+	return &CircuitBreaker{
+		cfg:     cfg,
+		entries: make(map[string]*breakerEntry),
+	}
+}
+
+func (b *CircuitBreaker) entry(key string) *breakerEntry {
+	e, ok := b.entries[key]
+	if !ok {
+		e = &breakerEntry{}
+		b.entries[key] = e
+	}
+	return e
+}
+
+// Allow reports whether an operation against key may proceed. While open it
+// refuses every attempt until OpenDuration has elapsed, then moves to
+// half-open and lets up to HalfOpenProbes attempts through; the first result
+// passed to RecordResult while half-open then closes or reopens it.
+func (b *CircuitBreaker) Allow(key string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	e := b.entry(key)
+	switch e.state {
+	case stateOpen:
+		if time.Since(e.openedAt) < time.Duration(b.cfg.OpenDuration) {
+			return false
+		}
+		e.state = stateHalfOpen
+		e.halfOpenLeft = b.cfg.HalfOpenProbes
+		fallthrough
+	case stateHalfOpen:
+		if e.halfOpenLeft <= 0 {
+			return false
+		}
+		e.halfOpenLeft--
+		return true
+	default:
+		return true
+	}
+}
+
+// RecordResult feeds the status code observed for key back into the
+// breaker. Any non-tripping status closes the breaker; a tripping status
+// reopens it immediately if seen while half-open, or once it has been seen
+// FailureThreshold times in a row while closed.
+func (b *CircuitBreaker) RecordResult(key string, code ua.StatusCode) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	e := b.entry(key)
+	if !IsBreakerTrippingStatus(code) {
+		e.state = stateClosed
+		e.failures = 0
+		return
+	}
+
+	e.failures++
+	if e.state == stateHalfOpen || e.failures >= b.cfg.FailureThreshold {
+		e.state = stateOpen
+		e.openedAt = time.Now()
+	}
+}
+
+// State returns the current state tag value for key: "closed", "open" or
+// "half_open".
+func (b *CircuitBreaker) State(key string) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.entry(key).state.String()
+}
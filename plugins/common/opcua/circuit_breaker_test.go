@@ -0,0 +1,90 @@
+package opcua
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gopcua/opcua/ua"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+)
+
+func TestCircuitBreakerOpensAfterThreshold(t *testing.T) {
+	b := NewCircuitBreaker(CircuitBreakerConfig{
+		FailureThreshold: 2,
+		OpenDuration:     config.Duration(time.Minute),
+		HalfOpenProbes:   1,
+	})
+
+	require.Equal(t, "closed", b.State("node1"))
+	require.True(t, b.Allow("node1"))
+
+	b.RecordResult("node1", ua.StatusBadCommunicationError)
+	require.Equal(t, "closed", b.State("node1"), "one failure shouldn't trip the breaker yet")
+
+	b.RecordResult("node1", ua.StatusBadCommunicationError)
+	require.Equal(t, "open", b.State("node1"))
+	require.False(t, b.Allow("node1"))
+
+	require.Equal(t, "closed", b.State("node2"), "breakers are tracked independently per key")
+}
+
+func TestCircuitBreakerRecoversThroughHalfOpen(t *testing.T) {
+	b := NewCircuitBreaker(CircuitBreakerConfig{
+		FailureThreshold: 1,
+		OpenDuration:     config.Duration(0),
+		HalfOpenProbes:   1,
+	})
+
+	b.RecordResult("node1", ua.StatusBadCommunicationError)
+	require.Equal(t, "open", b.State("node1"))
+
+	// OpenDuration is zero, so the next Allow should immediately move the
+	// breaker to half-open and let exactly one probe through.
+	require.True(t, b.Allow("node1"))
+	require.Equal(t, "half_open", b.State("node1"))
+	require.False(t, b.Allow("node1"), "only HalfOpenProbes attempts should be let through")
+
+	b.RecordResult("node1", ua.StatusOK)
+	require.Equal(t, "closed", b.State("node1"))
+}
+
+func TestCircuitBreakerReopensOnHalfOpenFailure(t *testing.T) {
+	b := NewCircuitBreaker(CircuitBreakerConfig{
+		FailureThreshold: 1,
+		OpenDuration:     config.Duration(0),
+		HalfOpenProbes:   1,
+	})
+
+	b.RecordResult("node1", ua.StatusBadCommunicationError)
+	require.True(t, b.Allow("node1"))
+	require.Equal(t, "half_open", b.State("node1"))
+
+	b.RecordResult("node1", ua.StatusBadSessionClosed)
+	require.Equal(t, "open", b.State("node1"))
+}
+
+func TestCircuitBreakerConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     CircuitBreakerConfig
+		wantErr bool
+	}{
+		{"valid", CircuitBreakerConfig{FailureThreshold: 1, OpenDuration: config.Duration(time.Second), HalfOpenProbes: 1}, false},
+		{"zero failure_threshold", CircuitBreakerConfig{OpenDuration: config.Duration(time.Second), HalfOpenProbes: 1}, true},
+		{"zero open_duration", CircuitBreakerConfig{FailureThreshold: 1, HalfOpenProbes: 1}, true},
+		{"zero half_open_probes", CircuitBreakerConfig{FailureThreshold: 1, OpenDuration: config.Duration(time.Second)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
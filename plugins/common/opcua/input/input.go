@@ -0,0 +1,168 @@
+// Package input contains the node/tag configuration shared by the OPC UA
+// based input plugins (polling and listener style alike).
+package input
+
+import (
+	"fmt"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/opcua"
+)
+
+// NodeSettings describes a single OPC UA node to read and the metric field
+// and tags it should be mapped to.
+type NodeSettings struct {
+	FieldName      string            `toml:"name"`
+	Namespace      string            `toml:"namespace"`
+	IdentifierType string            `toml:"identifier_type"`
+	Identifier     string            `toml:"identifier"`
+	DataType       string            `toml:"data_type,omitempty"`
+	Description    string            `toml:"description,omitempty"`
+	TagsSlice      [][]string        `toml:"tags"`
+	DefaultTags    map[string]string `toml:"default_tags"`
+
+	// Subscribe opts this node into subscription based updates instead of
+	// being re-read on every Gather. Unset (nil) falls back to the owning
+	// group's setting, or to polling for root nodes.
+	Subscribe *bool `toml:"subscribe"`
+}
+
+// NodeGroupSettings describes a group of nodes that share a metric name,
+// namespace, identifier type and set of tags, with per-node overrides.
+type NodeGroupSettings struct {
+	MetricName     string            `toml:"name"`
+	Namespace      string            `toml:"namespace"`
+	IdentifierType string            `toml:"identifier_type"`
+	TagsSlice      [][]string        `toml:"tags"`
+	Nodes          []NodeSettings    `toml:"nodes"`
+	DefaultTags    map[string]string `toml:"default_tags"`
+
+	// Subscribe is the group-wide default for whether its nodes are
+	// collected via subscription; individual nodes may override it.
+	Subscribe bool `toml:"subscribe"`
+
+	// DeadbandType/DeadbandValue configure the MonitoringFilter applied to
+	// every subscribed node in this group, e.g. "absolute" or "percent".
+	DeadbandType  string  `toml:"deadband_type"`
+	DeadbandValue float64 `toml:"deadband_value"`
+}
+
+// ShouldSubscribe reports whether the given node should be collected via
+// subscription rather than polled on every Gather, resolving the node's own
+// override against the group default.
+func (g NodeGroupSettings) ShouldSubscribe(node NodeSettings) bool {
+	if node.Subscribe != nil {
+		return *node.Subscribe
+	}
+	return g.Subscribe
+}
+
+// DiscoverySettings describes one subtree of the address space to walk when
+// the session is connected (and optionally again on a refresh interval) and
+// materialize into NodeMetricMapping entries, instead of requiring every node
+// to be listed statically. The walk does not run during Init; discovered
+// nodes appear only after the first successful Connect.
+type DiscoverySettings struct {
+	MetricName string `toml:"name"`
+
+	// StartingNode is the NodeId to start browsing from, e.g. "ns=2;i=1000".
+	StartingNode string `toml:"starting_node"`
+
+	// MaxDepth bounds how many Browse hops are followed below StartingNode.
+	MaxDepth int `toml:"max_depth"`
+
+	// ReferenceTypes restricts which reference types are followed while
+	// browsing, e.g. "HasComponent", "Organizes". Empty means all forward
+	// hierarchical references.
+	ReferenceTypes []string `toml:"reference_types"`
+
+	// Filter is a boolean expression of clauses ANDed together, each of the
+	// form `field op value` where field is one of nodeClass, browseName or
+	// dataType, op is `==`, `!=` or `matches`, and value is a literal or
+	// (for matches) a regular expression, e.g.:
+	//   nodeClass == "Variable" && browseName matches "Temp.*"
+	// dataType is compared against the NodeId string of the reference's
+	// TypeDefinition (e.g. "i=63"), not against a Variable's DataType
+	// attribute.
+	Filter string `toml:"filter"`
+
+	// RefreshInterval, when non-zero, re-runs discovery on that interval to
+	// pick up nodes added to the server after Init.
+	RefreshInterval config.Duration `toml:"refresh_interval"`
+
+	DefaultTags map[string]string `toml:"default_tags"`
+}
+
+// InputClientConfig is the configuration shared by OPC UA input plugins that
+// read one-off or grouped node values: the connection options plus the list
+// of nodes/groups to collect.
+type InputClientConfig struct {
+	opcua.OpcUAClientConfig
+
+	MetricName string              `toml:"name"`
+	RootNodes  []NodeSettings      `toml:"nodes"`
+	Groups     []NodeGroupSettings `toml:"group"`
+	Discovery  []DiscoverySettings `toml:"discovery"`
+}
+
+// NodeMetricMapping ties a resolved node to the metric field/tags it should
+// be reported under.
+type NodeMetricMapping struct {
+	Tag        NodeSettings
+	MetricName string
+	MetricTags map[string]string
+
+	// Subscribe, when true, means this node's value is pushed via an OPC UA
+	// subscription rather than read on every Gather.
+	Subscribe     bool
+	DeadbandType  string
+	DeadbandValue float64
+}
+
+// NewNodeMetricMapping resolves the tags for a single node: groupTags form
+// the base, and the node either overrides them with its own DefaultTags or,
+// if none are set, layers its own TagsSlice on top.
+func NewNodeMetricMapping(metricName string, tag NodeSettings, groupTags map[string]string) (NodeMetricMapping, error) {
+	if tag.FieldName == "" {
+		return NodeMetricMapping{}, fmt.Errorf("empty name in %q", metricName)
+	}
+
+	tags := make(map[string]string, len(groupTags)+len(tag.TagsSlice)+len(tag.DefaultTags))
+	for k, v := range groupTags {
+		tags[k] = v
+	}
+
+	if tag.DefaultTags != nil {
+		for k, v := range tag.DefaultTags {
+			tags[k] = v
+		}
+	} else {
+		for _, pair := range tag.TagsSlice {
+			if len(pair) != 2 {
+				return NodeMetricMapping{}, fmt.Errorf("tag %v is not a pair in %q", pair, tag.FieldName)
+			}
+			tags[pair[0]] = pair[1]
+		}
+	}
+
+	return NodeMetricMapping{
+		Tag:        tag,
+		MetricName: metricName,
+		MetricTags: tags,
+	}, nil
+}
+
+// OpcUAInputClient is the contract the opcua input plugin drives: connect,
+// resolve the configured nodes, refresh their values and read them back one
+// at a time. Gather reads nodes through ReadValue individually (rather than
+// the whole Metrics table at once) so a panic recovered from one node's
+// value doesn't take down the rest of the batch; see the panic-recovery
+// middleware in the opcua package.
+type OpcUAInputClient interface {
+	Init() error
+	Connect() error
+	Stop() error
+	Read() error
+	Metrics() []NodeMetricMapping
+	ReadValue(idx int) (interface{}, error)
+}
@@ -0,0 +1,155 @@
+package opcua
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OIDCClientCredentialsConfig fetches an OAuth2/OIDC access token via the
+// client-credentials grant so it can be presented to the server as an
+// IssuedIdentityToken.
+type OIDCClientCredentialsConfig struct {
+	TokenURL     string   `toml:"token_url"`
+	ClientID     string   `toml:"client_id"`
+	ClientSecret string   `toml:"client_secret"`
+	Scopes       []string `toml:"scopes"`
+	Audience     string   `toml:"audience"`
+}
+
+// IssuedTokenConfig configures the `IssuedToken` auth_method: either a
+// static token, a token read from a file, or one fetched (and refreshed) via
+// OIDC client-credentials.
+type IssuedTokenConfig struct {
+	Token     string                       `toml:"token"`
+	TokenFile string                       `toml:"token_file"`
+	OIDC      *OIDCClientCredentialsConfig `toml:"oidc"`
+
+	mu          sync.Mutex
+	cached      string
+	cachedUntil time.Time
+}
+
+func (c *IssuedTokenConfig) Validate() error {
+	set := 0
+	if c.Token != "" {
+		set++
+	}
+	if c.TokenFile != "" {
+		set++
+	}
+	if c.OIDC != nil {
+		set++
+	}
+	if set != 1 {
+		return fmt.Errorf("exactly one of token, token_file or oidc must be set for auth_method = \"IssuedToken\"")
+	}
+
+	if c.OIDC != nil {
+		if c.OIDC.TokenURL == "" || c.OIDC.ClientID == "" || c.OIDC.ClientSecret == "" {
+			return fmt.Errorf("oidc requires token_url, client_id and client_secret")
+		}
+	}
+
+	return nil
+}
+
+// Resolve returns the current access token, fetching or re-reading it if the
+// previously cached one is missing or close enough to expiry that it should
+// be refreshed before use.
+func (c *IssuedTokenConfig) Resolve(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.Token != "" {
+		return c.Token, nil
+	}
+
+	if c.TokenFile != "" {
+		raw, err := os.ReadFile(c.TokenFile)
+		if err != nil {
+			return "", fmt.Errorf("reading token_file: %w", err)
+		}
+		return strings.TrimSpace(string(raw)), nil
+	}
+
+	if time.Now().Before(c.cachedUntil) {
+		return c.cached, nil
+	}
+
+	token, expiresIn, err := c.OIDC.fetch(ctx)
+	if err != nil {
+		return "", fmt.Errorf("fetching oidc token: %w", err)
+	}
+
+	c.cached = token
+	// Refresh a little early so a session activation never races an
+	// about-to-expire token.
+	c.cachedUntil = time.Now().Add(expiresIn - 30*time.Second)
+
+	return c.cached, nil
+}
+
+// Invalidate drops the cached token, forcing the next Resolve call to fetch a
+// fresh one. Callers use this after a Bad_SessionIdInvalid response, which
+// often indicates the server rejected the token the session was activated
+// with.
+func (c *IssuedTokenConfig) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cachedUntil = time.Time{}
+}
+
+func (o *OIDCClientCredentialsConfig) fetch(ctx context.Context) (string, time.Duration, error) {
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {o.ClientID},
+		"client_secret": {o.ClientSecret},
+	}
+	if len(o.Scopes) > 0 {
+		form.Set("scope", strings.Join(o.Scopes, " "))
+	}
+	if o.Audience != "" {
+		form.Set("audience", o.Audience)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("token endpoint returned %s", resp.Status)
+	}
+
+	var body struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", 0, fmt.Errorf("decoding token response: %w", err)
+	}
+	if body.AccessToken == "" {
+		return "", 0, fmt.Errorf("token endpoint response had no access_token")
+	}
+
+	expiresIn := time.Duration(body.ExpiresIn) * time.Second
+	if expiresIn <= 0 {
+		expiresIn = 5 * time.Minute
+	}
+
+	return body.AccessToken, expiresIn, nil
+}
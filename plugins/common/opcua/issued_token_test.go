@@ -0,0 +1,223 @@
+package opcua
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssuedTokenConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     IssuedTokenConfig
+		wantErr bool
+	}{
+		{"token only", IssuedTokenConfig{Token: "abc"}, false},
+		{"token_file only", IssuedTokenConfig{TokenFile: "/tmp/token"}, false},
+		{"oidc only", IssuedTokenConfig{OIDC: &OIDCClientCredentialsConfig{TokenURL: "https://auth.example.com", ClientID: "id", ClientSecret: "secret"}}, false},
+		{"none set", IssuedTokenConfig{}, true},
+		{"token and token_file", IssuedTokenConfig{Token: "abc", TokenFile: "/tmp/token"}, true},
+		{"oidc missing client_secret", IssuedTokenConfig{OIDC: &OIDCClientCredentialsConfig{TokenURL: "https://auth.example.com", ClientID: "id"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestIssuedTokenConfigResolveStaticToken(t *testing.T) {
+	cfg := IssuedTokenConfig{Token: "static-token"}
+
+	token, err := cfg.Resolve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "static-token", token)
+}
+
+func TestIssuedTokenConfigResolveTokenFile(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "opcua-token")
+	require.NoError(t, err)
+	_, err = f.WriteString("file-token\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	cfg := IssuedTokenConfig{TokenFile: f.Name()}
+
+	token, err := cfg.Resolve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "file-token", token)
+}
+
+func TestIssuedTokenConfigResolveOIDCFetchesAndCaches(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		require.NoError(t, r.ParseForm())
+		require.Equal(t, "client_credentials", r.FormValue("grant_type"))
+		require.Equal(t, "id", r.FormValue("client_id"))
+		require.Equal(t, "secret", r.FormValue("client_secret"))
+
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "fetched-token",
+			"expires_in":   3600,
+		}))
+	}))
+	defer server.Close()
+
+	cfg := IssuedTokenConfig{
+		OIDC: &OIDCClientCredentialsConfig{
+			TokenURL:     server.URL,
+			ClientID:     "id",
+			ClientSecret: "secret",
+		},
+	}
+
+	token, err := cfg.Resolve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "fetched-token", token)
+	require.Equal(t, 1, requests)
+
+	// A second Resolve before expiry should reuse the cached token rather
+	// than hitting the token endpoint again.
+	token, err = cfg.Resolve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "fetched-token", token)
+	require.Equal(t, 1, requests)
+}
+
+func TestIssuedTokenConfigResolveOIDCRefreshesNearExpiry(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "fetched-token",
+			"expires_in":   1,
+		}))
+	}))
+	defer server.Close()
+
+	cfg := IssuedTokenConfig{
+		OIDC: &OIDCClientCredentialsConfig{
+			TokenURL:     server.URL,
+			ClientID:     "id",
+			ClientSecret: "secret",
+		},
+	}
+
+	_, err := cfg.Resolve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, requests)
+
+	// expires_in (1s) minus the 30s early-refresh margin leaves cachedUntil
+	// already in the past, so the very next Resolve should refetch.
+	_, err = cfg.Resolve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, requests)
+}
+
+func TestIssuedTokenConfigInvalidateForcesRefetch(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "fetched-token",
+			"expires_in":   3600,
+		}))
+	}))
+	defer server.Close()
+
+	cfg := IssuedTokenConfig{
+		OIDC: &OIDCClientCredentialsConfig{
+			TokenURL:     server.URL,
+			ClientID:     "id",
+			ClientSecret: "secret",
+		},
+	}
+
+	_, err := cfg.Resolve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, requests)
+
+	cfg.Invalidate()
+
+	_, err = cfg.Resolve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, requests)
+}
+
+func TestIssuedTokenConfigResolveOIDCErrorResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	cfg := IssuedTokenConfig{
+		OIDC: &OIDCClientCredentialsConfig{
+			TokenURL:     server.URL,
+			ClientID:     "id",
+			ClientSecret: "secret",
+		},
+	}
+
+	_, err := cfg.Resolve(context.Background())
+	require.Error(t, err)
+}
+
+func TestIssuedTokenConfigResolveOIDCMissingAccessToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(map[string]interface{}{
+			"expires_in": 3600,
+		}))
+	}))
+	defer server.Close()
+
+	cfg := IssuedTokenConfig{
+		OIDC: &OIDCClientCredentialsConfig{
+			TokenURL:     server.URL,
+			ClientID:     "id",
+			ClientSecret: "secret",
+		},
+	}
+
+	_, err := cfg.Resolve(context.Background())
+	require.Error(t, err)
+}
+
+func TestOIDCClientCredentialsConfigFetchDefaultsExpiry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "fetched-token",
+		}))
+	}))
+	defer server.Close()
+
+	oidc := &OIDCClientCredentialsConfig{
+		TokenURL:     server.URL,
+		ClientID:     "id",
+		ClientSecret: "secret",
+		Scopes:       []string{"opcua", "read"},
+		Audience:     "opcua-api",
+	}
+
+	token, expiresIn, err := oidc.fetch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "fetched-token", token)
+	require.Equal(t, 5*time.Minute, expiresIn)
+}
@@ -0,0 +1,254 @@
+package opcua
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gopcua/opcua"
+	"github.com/gopcua/opcua/ua"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+)
+
+// OpcUAWorkarounds contains knobs for dealing with non-conformant servers.
+type OpcUAWorkarounds struct {
+	AdditionalValidStatusCodes []string `toml:"additional_valid_status_codes"`
+}
+
+// OpcUAClientConfig contains the options needed to open and authenticate a
+// session against an OPC UA server. It is shared by every plugin built on
+// top of this package.
+type OpcUAClientConfig struct {
+	Endpoint       string             `toml:"endpoint"`
+	SecurityPolicy string             `toml:"security_policy"`
+	SecurityMode   string             `toml:"security_mode"`
+	Certificate    string             `toml:"certificate"`
+	PrivateKey     string             `toml:"private_key"`
+	Username       string             `toml:"username"`
+	Password       string             `toml:"password"`
+	AuthMethod     string             `toml:"auth_method"`
+	IssuedToken    *IssuedTokenConfig `toml:"issued_token"`
+	ConnectTimeout config.Duration    `toml:"connect_timeout"`
+	RequestTimeout config.Duration    `toml:"request_timeout"`
+	Workarounds    OpcUAWorkarounds   `toml:"workarounds"`
+
+	// CircuitBreaker, when set, rejects connection attempts immediately
+	// once the endpoint has failed to connect FailureThreshold times in a
+	// row, instead of blocking on ConnectTimeout every time.
+	CircuitBreaker *CircuitBreakerConfig `toml:"circuit_breaker"`
+}
+
+// endpointBreakerKey is the CircuitBreaker key used for connection attempts,
+// namespaced separately from the per-node keys used by callers that track
+// read failures (e.g. plugins/inputs/opcua's ReadClient).
+const endpointBreakerKey = "endpoint"
+
+// OpcUAClient wraps a gopcua session together with the configuration used to
+// create it, so reconnects can reuse the same options.
+type OpcUAClient struct {
+	Config *OpcUAClientConfig
+	Client *opcua.Client
+	Log    telegraf.Logger
+
+	// Breaker is nil unless Config.CircuitBreaker is set.
+	Breaker *CircuitBreaker
+}
+
+func (o *OpcUAClientConfig) Validate() error {
+	if o.Endpoint == "" {
+		return fmt.Errorf("endpoint is required")
+	}
+
+	switch o.AuthMethod {
+	case "", "Anonymous", "UserName", "Certificate":
+	case "IssuedToken":
+		if o.IssuedToken == nil {
+			return fmt.Errorf("issued_token is required when auth_method = \"IssuedToken\"")
+		}
+		if err := o.IssuedToken.Validate(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("invalid auth_method %q", o.AuthMethod)
+	}
+
+	if o.CircuitBreaker != nil {
+		if err := o.CircuitBreaker.Validate(); err != nil {
+			return fmt.Errorf("circuit_breaker: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// CreateClient validates this configuration and returns a client ready to
+// Connect(); the gopcua options themselves are built on every Connect.
+func (o *OpcUAClientConfig) CreateClient(log telegraf.Logger) (*OpcUAClient, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+
+	client := &OpcUAClient{
+		Config: o,
+		Log:    log,
+	}
+
+	if o.CircuitBreaker != nil {
+		client.Breaker = NewCircuitBreaker(*o.CircuitBreaker)
+	}
+
+	return client, nil
+}
+
+// baseOptions returns the connection options that don't depend on the
+// identity token, so they can be reused every time the client is (re)built.
+func (o *OpcUAClientConfig) baseOptions() []opcua.Option {
+	return []opcua.Option{
+		opcua.SecurityPolicy(o.SecurityPolicy),
+		opcua.SecurityModeString(o.SecurityMode),
+		opcua.Certificate([]byte(o.Certificate)),
+		opcua.PrivateKey([]byte(o.PrivateKey)),
+		opcua.RequestTimeout(time.Duration(o.RequestTimeout)),
+	}
+}
+
+// identityOptions translates AuthMethod into the gopcua identity token
+// option(s) to present during CreateSession. For IssuedToken it resolves
+// (and, if cached, refreshes) the token from the configured source.
+func (o *OpcUAClientConfig) identityOptions(ctx context.Context) ([]opcua.Option, error) {
+	switch strings.ToLower(o.AuthMethod) {
+	case "username":
+		return []opcua.Option{opcua.AuthUsername(o.Username, o.Password)}, nil
+	case "certificate":
+		return []opcua.Option{opcua.AuthCertificate([]byte(o.Certificate))}, nil
+	case "issuedtoken":
+		token, err := o.IssuedToken.Resolve(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return []opcua.Option{opcua.AuthIssuedToken([]byte(token))}, nil
+	case "anonymous", "":
+		return []opcua.Option{opcua.AuthAnonymous()}, nil
+	default:
+		return nil, fmt.Errorf("unsupported auth_method %q", o.AuthMethod)
+	}
+}
+
+// Connect (re)builds the gopcua client with a fresh identity token and opens
+// a session. Rebuilding on every call keeps IssuedToken sessions activated
+// with a token that hasn't expired, including after a forced Invalidate
+// following a Bad_SessionIdInvalid response.
+func (o *OpcUAClient) Connect(ctx context.Context) (err error) {
+	if o.Breaker != nil && !o.Breaker.Allow(endpointBreakerKey) {
+		return fmt.Errorf("connecting to %q: %w", o.Config.Endpoint, ErrCircuitOpen)
+	}
+
+	// Whatever consumed the Allow() above (including a half-open probe)
+	// must report an outcome on every exit path, or a probe that failed
+	// before reaching client.Connect never closes out and the breaker can
+	// wedge half-open forever. Deferring on the named return covers every
+	// return statement below, success or failure alike.
+	if o.Breaker != nil {
+		defer func() {
+			code := ua.StatusOK
+			if err != nil {
+				code = ua.StatusBadCommunicationError
+			}
+			o.recordBreakerResult(code)
+		}()
+	}
+
+	identityOpts, identErr := o.Config.identityOptions(ctx)
+	if identErr != nil {
+		return fmt.Errorf("building identity token: %w", identErr)
+	}
+
+	opts := append(o.Config.baseOptions(), identityOpts...)
+
+	client, clientErr := opcua.NewClient(o.Config.Endpoint, opts...)
+	if clientErr != nil {
+		return fmt.Errorf("creating client: %w", clientErr)
+	}
+
+	dialCtx, cancel := context.WithTimeout(ctx, time.Duration(o.Config.ConnectTimeout))
+	defer cancel()
+
+	if dialErr := client.Connect(dialCtx); dialErr != nil {
+		return fmt.Errorf("connecting to %q: %w", o.Config.Endpoint, dialErr)
+	}
+
+	if o.Client != nil {
+		if closeErr := o.Client.CloseWithContext(ctx); closeErr != nil {
+			o.Log.Debugf("opcua: closing previous session to %q: %s", o.Config.Endpoint, closeErr)
+		}
+	}
+
+	o.Client = client
+
+	return nil
+}
+
+// recordBreakerResult feeds the outcome of a connection attempt back into
+// the endpoint breaker, if one is configured, and logs a state transition.
+func (o *OpcUAClient) recordBreakerResult(code ua.StatusCode) {
+	if o.Breaker == nil {
+		return
+	}
+
+	before := o.Breaker.State(endpointBreakerKey)
+	o.Breaker.RecordResult(endpointBreakerKey, code)
+	after := o.Breaker.State(endpointBreakerKey)
+
+	if before != after {
+		o.Log.Warnf("opcua: circuit breaker for %q transitioned %s -> %s", o.Config.Endpoint, before, after)
+	}
+}
+
+// ReauthenticateOnInvalidSession drops any cached IssuedToken and, if this
+// status code indicates the session was rejected, reconnects with a fresh
+// one. The reconnect runs against a context scoped to ConnectTimeout alone,
+// detached from the caller's ctx: ctx is typically a per-Gather context
+// bounded by RequestTimeout, which may have little or no time left by the
+// time a read fails, and would otherwise starve the reconnect of its full
+// connect budget.
+func (o *OpcUAClient) ReauthenticateOnInvalidSession(ctx context.Context, code ua.StatusCode) error {
+	if code != ua.StatusBadSessionIDInvalid {
+		return nil
+	}
+	if strings.ToLower(o.Config.AuthMethod) != "issuedtoken" {
+		return nil
+	}
+
+	o.Config.IssuedToken.Invalidate()
+
+	reconnectCtx, cancel := context.WithTimeout(context.Background(), time.Duration(o.Config.ConnectTimeout))
+	defer cancel()
+
+	return o.Connect(reconnectCtx)
+}
+
+func (o *OpcUAClient) Disconnect(ctx context.Context) error {
+	if o.Client == nil {
+		return nil
+	}
+	return o.Client.CloseWithContext(ctx)
+}
+
+// StatusCodeOK reports whether the given status code should be treated as a
+// success, taking the configured workarounds into account.
+func (o *OpcUAClientConfig) StatusCodeOK(code ua.StatusCode) bool {
+	if code == ua.StatusOK {
+		return true
+	}
+
+	for _, valid := range o.Workarounds.AdditionalValidStatusCodes {
+		if valid == code.Error() {
+			return true
+		}
+	}
+
+	return false
+}
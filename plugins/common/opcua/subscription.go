@@ -0,0 +1,13 @@
+package opcua
+
+import "github.com/influxdata/telegraf/config"
+
+// SubscriptionConfig controls the CreateSubscription/CreateMonitoredItems
+// parameters used when a node is read via subscription instead of being
+// polled on every Gather.
+type SubscriptionConfig struct {
+	PublishingInterval config.Duration `toml:"publishing_interval"`
+	SamplingInterval   config.Duration `toml:"sampling_interval"`
+	QueueSize          uint32          `toml:"queue_size"`
+	DiscardOldest      bool            `toml:"discard_oldest"`
+}
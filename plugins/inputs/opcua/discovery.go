@@ -0,0 +1,258 @@
+package opcua
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gopcua/opcua/ua"
+
+	"github.com/influxdata/telegraf/plugins/common/opcua/input"
+)
+
+// discoverer tracks every NodeId a DiscoverySettings entry has already
+// materialized, so a refresh only appends genuinely new nodes. mu guards
+// seen and also serializes browse/refresh passes for this discoverer, since
+// a refresh tick can otherwise overlap a still-running previous pass.
+type discoverer struct {
+	settings input.DiscoverySettings
+	filter   *discoveryFilter
+	seen     map[string]bool
+	mu       sync.Mutex
+}
+
+func newDiscoverer(settings input.DiscoverySettings) (*discoverer, error) {
+	filter, err := parseDiscoveryFilter(settings.Filter)
+	if err != nil {
+		return nil, fmt.Errorf("parsing filter for discovery %q: %w", settings.MetricName, err)
+	}
+
+	return &discoverer{
+		settings: settings,
+		filter:   filter,
+		seen:     make(map[string]bool),
+	}, nil
+}
+
+// runDiscoverers walks every configured discovery subtree and appends any
+// newly matched node to r.NodeMetricMapping, extending pollIndices and
+// LastReceivedData to match. It's called from Connect for the initial full
+// pass; Init does not browse. Refresh ticks call refreshOne for a single
+// discoverer instead.
+func (r *ReadClient) runDiscoverers(ctx context.Context) error {
+	for _, d := range r.discoverers {
+		if err := r.refreshOne(ctx, d); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// refreshOne browses a single discoverer's subtree and appends any newly
+// matched node to r.NodeMetricMapping, extending pollIndices and
+// LastReceivedData to match. d.mu serializes this against any other pass
+// over the same discoverer (the initial pass from Connect or an overlapping refresh
+// tick), since browse mutates d.seen without its own locking.
+func (r *ReadClient) refreshOne(ctx context.Context, d *discoverer) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	nodes, err := r.discover(ctx, d)
+	if err != nil {
+		return fmt.Errorf("discovery %q: %w", d.settings.MetricName, err)
+	}
+
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	r.mappingMu.Lock()
+	defer r.mappingMu.Unlock()
+
+	for _, tag := range nodes {
+		mapping, err := input.NewNodeMetricMapping(d.settings.MetricName, tag, nil)
+		if err != nil {
+			return err
+		}
+		r.NodeMetricMapping = append(r.NodeMetricMapping, mapping)
+		r.LastReceivedData = append(r.LastReceivedData, NodeValue{})
+		r.pollIndices = append(r.pollIndices, len(r.NodeMetricMapping)-1)
+	}
+
+	return nil
+}
+
+// discover browses d.settings.StartingNode down to MaxDepth, returning a
+// NodeSettings for every reference that passes the reference-type and
+// filter-expression checks and hasn't already been returned by a previous
+// call (e.g. from a refresh tick).
+func (r *ReadClient) discover(ctx context.Context, d *discoverer) ([]input.NodeSettings, error) {
+	start, err := ua.ParseNodeID(d.settings.StartingNode)
+	if err != nil {
+		return nil, fmt.Errorf("parsing starting_node %q: %w", d.settings.StartingNode, err)
+	}
+
+	var found []input.NodeSettings
+	if err := r.browse(ctx, d, start, 0, &found); err != nil {
+		return nil, err
+	}
+
+	return found, nil
+}
+
+func (r *ReadClient) browse(ctx context.Context, d *discoverer, parent *ua.NodeID, depth int, found *[]input.NodeSettings) error {
+	if depth >= d.settings.MaxDepth {
+		return nil
+	}
+
+	req := &ua.BrowseRequest{
+		NodesToBrowse: []*ua.BrowseDescription{
+			{
+				NodeID:          parent,
+				BrowseDirection: ua.BrowseDirectionForward,
+				ReferenceTypeID: ua.NewTwoByteNodeID(33), // HierarchicalReferences, refined below
+				IncludeSubtypes: true,
+				NodeClassMask:   uint32(ua.NodeClassAll),
+				ResultMask:      uint32(ua.BrowseResultMaskAll),
+			},
+		},
+	}
+
+	var resp *ua.BrowseResponse
+	err := recoverCall(parent.String(), func() error {
+		var callErr error
+		resp, callErr = r.Client.BrowseWithContext(ctx, req)
+		return callErr
+	})
+	if err != nil {
+		return fmt.Errorf("browsing %s: %w", parent, err)
+	}
+
+	for _, result := range resp.Results {
+		for _, ref := range result.References {
+			if !d.referenceTypeAllowed(ref.ReferenceTypeID) {
+				continue
+			}
+
+			candidate := discoveryCandidate{
+				nodeClass:  strings.TrimPrefix(ref.NodeClass.String(), "NodeClass"),
+				browseName: ref.BrowseName.Name,
+				dataType:   ref.TypeDefinition.NodeID.String(),
+			}
+
+			id := ref.NodeID.NodeID
+			key := id.String()
+
+			if d.filter.Match(candidate) && !d.seen[key] {
+				d.seen[key] = true
+				*found = append(*found, nodeSettingsFromID(id, ref.BrowseName.Name, d.settings.DefaultTags))
+			}
+
+			if err := r.browse(ctx, d, id, depth+1, found); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// wellKnownReferenceTypes maps the namespace-0 reference type BrowseNames
+// accepted in ReferenceTypes to their numeric identifier, since a Browse
+// result only gives us the ReferenceTypeID (e.g. "ns=0;i=47"), never the
+// name back.
+var wellKnownReferenceTypes = map[string]uint32{
+	"References":                31,
+	"NonHierarchicalReferences": 32,
+	"HierarchicalReferences":    33,
+	"HasChild":                  34,
+	"Organizes":                 35,
+	"HasEventSource":            36,
+	"HasModellingRule":          37,
+	"HasEncoding":               38,
+	"HasDescription":            39,
+	"HasTypeDefinition":         40,
+	"GeneratesEvent":            41,
+	"Aggregates":                44,
+	"HasSubtype":                45,
+	"HasProperty":               46,
+	"HasComponent":              47,
+	"HasNotifier":               48,
+	"HasOrderedComponent":       49,
+}
+
+// referenceTypeAllowed reports whether refs of this type should be followed,
+// based on d.settings.ReferenceTypes. An empty list allows every reference.
+func (d *discoverer) referenceTypeAllowed(id *ua.NodeID) bool {
+	if len(d.settings.ReferenceTypes) == 0 {
+		return true
+	}
+
+	if id.Namespace() != 0 {
+		return false
+	}
+
+	for _, want := range d.settings.ReferenceTypes {
+		if numeric, ok := wellKnownReferenceTypes[want]; ok && id.IntID() == numeric {
+			return true
+		}
+	}
+
+	return false
+}
+
+// nodeSettingsFromID materializes a browsed NodeId back into the same
+// NodeSettings shape used by statically configured nodes.
+func nodeSettingsFromID(id *ua.NodeID, fieldName string, tags map[string]string) input.NodeSettings {
+	namespace := strconv.Itoa(int(id.Namespace()))
+
+	idType, identifier := "s", id.StringID()
+	switch {
+	case id.IntID() != 0:
+		idType, identifier = "i", strconv.Itoa(int(id.IntID()))
+	case len(id.GUID()) > 0:
+		idType, identifier = "g", id.GUID().String()
+	case len(id.ByteStringID()) > 0:
+		idType, identifier = "b", string(id.ByteStringID())
+	}
+
+	return input.NodeSettings{
+		FieldName:      fieldName,
+		Namespace:      namespace,
+		IdentifierType: idType,
+		Identifier:     identifier,
+		DefaultTags:    tags,
+	}
+}
+
+// startDiscoveryRefresh runs any discovery entries with a RefreshInterval on
+// their own ticker for the lifetime of the session.
+func (r *ReadClient) startDiscoveryRefresh(ctx context.Context) {
+	for _, d := range r.discoverers {
+		if d.settings.RefreshInterval <= 0 {
+			continue
+		}
+
+		go func(d *discoverer) {
+			defer recoverGoroutine(r.Log, "discovery refresh")
+
+			ticker := time.NewTicker(time.Duration(d.settings.RefreshInterval))
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					if err := r.refreshOne(ctx, d); err != nil {
+						r.Log.Errorf("opcua: refreshing discovery %q: %s", d.settings.MetricName, err)
+					}
+				}
+			}
+		}(d)
+	}
+}
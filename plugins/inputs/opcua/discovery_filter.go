@@ -0,0 +1,107 @@
+package opcua
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// discoveryFilter evaluates the clauses of a DiscoverySettings.Filter
+// expression against a candidate node. Clauses are joined with `&&`; every
+// clause must match for the node to be selected.
+type discoveryFilter struct {
+	clauses []discoveryClause
+}
+
+type discoveryClause struct {
+	field string
+	op    string
+	value string
+	re    *regexp.Regexp
+}
+
+type discoveryCandidate struct {
+	nodeClass  string
+	browseName string
+	dataType   string
+}
+
+func parseDiscoveryFilter(expr string) (*discoveryFilter, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return &discoveryFilter{}, nil
+	}
+
+	var clauses []discoveryClause
+	for _, raw := range strings.Split(expr, "&&") {
+		clause, err := parseDiscoveryClause(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, clause)
+	}
+
+	return &discoveryFilter{clauses: clauses}, nil
+}
+
+func parseDiscoveryClause(raw string) (discoveryClause, error) {
+	for _, op := range []string{"matches", "==", "!="} {
+		idx := strings.Index(raw, " "+op+" ")
+		if idx < 0 {
+			continue
+		}
+
+		field := strings.TrimSpace(raw[:idx])
+		value := strings.Trim(strings.TrimSpace(raw[idx+len(op)+2:]), `"`)
+
+		switch field {
+		case "nodeClass", "browseName", "dataType":
+		default:
+			return discoveryClause{}, fmt.Errorf("unknown filter field %q", field)
+		}
+
+		clause := discoveryClause{field: field, op: op, value: value}
+		if op == "matches" {
+			re, err := regexp.Compile(value)
+			if err != nil {
+				return discoveryClause{}, fmt.Errorf("compiling filter regexp %q: %w", value, err)
+			}
+			clause.re = re
+		}
+
+		return clause, nil
+	}
+
+	return discoveryClause{}, fmt.Errorf("invalid filter clause %q, want `field op value`", raw)
+}
+
+func (f *discoveryFilter) Match(c discoveryCandidate) bool {
+	for _, clause := range f.clauses {
+		var actual string
+		switch clause.field {
+		case "nodeClass":
+			actual = c.nodeClass
+		case "browseName":
+			actual = c.browseName
+		case "dataType":
+			actual = c.dataType
+		}
+
+		switch clause.op {
+		case "==":
+			if actual != clause.value {
+				return false
+			}
+		case "!=":
+			if actual == clause.value {
+				return false
+			}
+		case "matches":
+			if !clause.re.MatchString(actual) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
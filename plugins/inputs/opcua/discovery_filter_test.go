@@ -0,0 +1,72 @@
+package opcua
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoveryFilterMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		expr   string
+		cand   discoveryCandidate
+		wantOK bool
+	}{
+		{
+			name:   "empty filter matches everything",
+			expr:   "",
+			cand:   discoveryCandidate{nodeClass: "Object"},
+			wantOK: true,
+		},
+		{
+			name:   "single equality clause matches",
+			expr:   `nodeClass == "Variable"`,
+			cand:   discoveryCandidate{nodeClass: "Variable"},
+			wantOK: true,
+		},
+		{
+			name:   "single equality clause does not match",
+			expr:   `nodeClass == "Variable"`,
+			cand:   discoveryCandidate{nodeClass: "Object"},
+			wantOK: false,
+		},
+		{
+			name:   "combined clauses all must match",
+			expr:   `nodeClass == "Variable" && browseName matches "Temp.*"`,
+			cand:   discoveryCandidate{nodeClass: "Variable", browseName: "TempSensor1"},
+			wantOK: true,
+		},
+		{
+			name:   "combined clauses fail if one does not match",
+			expr:   `nodeClass == "Variable" && browseName matches "Temp.*"`,
+			cand:   discoveryCandidate{nodeClass: "Variable", browseName: "Pressure1"},
+			wantOK: false,
+		},
+		{
+			name:   "not-equal clause",
+			expr:   `nodeClass != "Method"`,
+			cand:   discoveryCandidate{nodeClass: "Variable"},
+			wantOK: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := parseDiscoveryFilter(tt.expr)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantOK, f.Match(tt.cand))
+		})
+	}
+}
+
+func TestDiscoveryFilterParseErrors(t *testing.T) {
+	_, err := parseDiscoveryFilter(`nodeClass invalidop "Variable"`)
+	require.Error(t, err)
+
+	_, err = parseDiscoveryFilter(`unknownField == "x"`)
+	require.Error(t, err)
+
+	_, err = parseDiscoveryFilter(`browseName matches "["`)
+	require.Error(t, err)
+}
@@ -0,0 +1,58 @@
+package opcua
+
+import (
+	"testing"
+
+	"github.com/gopcua/opcua/ua"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/plugins/common/opcua/input"
+)
+
+func TestReferenceTypeAllowed(t *testing.T) {
+	tests := []struct {
+		name      string
+		refTypes  []string
+		candidate *ua.NodeID
+		wantOK    bool
+	}{
+		{
+			name:      "empty list allows everything",
+			refTypes:  nil,
+			candidate: ua.NewTwoByteNodeID(47), // HasComponent
+			wantOK:    true,
+		},
+		{
+			name:      "well-known name matches its namespace-0 NodeId",
+			refTypes:  []string{"HasComponent"},
+			candidate: ua.NewTwoByteNodeID(47),
+			wantOK:    true,
+		},
+		{
+			name:      "well-known name does not match a different NodeId",
+			refTypes:  []string{"Organizes"},
+			candidate: ua.NewTwoByteNodeID(47), // HasComponent
+			wantOK:    false,
+		},
+		{
+			name:      "multiple configured names, one matches",
+			refTypes:  []string{"Organizes", "HasComponent"},
+			candidate: ua.NewTwoByteNodeID(47),
+			wantOK:    true,
+		},
+		{
+			name:      "non namespace-0 reference type never matches",
+			refTypes:  []string{"HasComponent"},
+			candidate: ua.NewFourByteNodeID(1, 47),
+			wantOK:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := newDiscoverer(input.DiscoverySettings{ReferenceTypes: tt.refTypes})
+			require.NoError(t, err)
+			require.Equal(t, tt.wantOK, d.referenceTypeAllowed(tt.candidate))
+		})
+	}
+}
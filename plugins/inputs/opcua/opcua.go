@@ -0,0 +1,132 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package opcua
+
+import (
+	_ "embed"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/opcua/input"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// OpcUA is a poll based input plugin that reads one or more OPC UA nodes on
+// every Gather and emits one metric per distinct metric name and tag set.
+type OpcUA struct {
+	ReadClientConfig
+
+	Log telegraf.Logger `toml:"-"`
+
+	client    input.OpcUAInputClient
+	connected bool
+}
+
+func (*OpcUA) SampleConfig() string {
+	return sampleConfig
+}
+
+func (o *OpcUA) Init() error {
+	client, err := NewReadClient(o.ReadClientConfig, o.Log)
+	if err != nil {
+		return fmt.Errorf("creating client: %w", err)
+	}
+	o.client = client
+
+	return o.client.Init()
+}
+
+// Gather connects and refreshes the whole node set, then reads each node's
+// value individually: readNodeValue recovers a panic from any single node
+// so one misbehaving node can't take down the rest of the batch.
+func (o *OpcUA) Gather(acc telegraf.Accumulator) error {
+	if !o.connected {
+		if err := o.client.Connect(); err != nil {
+			return fmt.Errorf("connecting: %w", err)
+		}
+		o.connected = true
+	}
+
+	if err := o.client.Read(); err != nil {
+		return fmt.Errorf("reading nodes: %w", err)
+	}
+
+	groups := make(map[string]*metricGroup)
+
+	for idx, mapping := range o.client.Metrics() {
+		value, err := readNodeValue(o.client, idx, mapping)
+		if err != nil {
+			o.Log.Errorf("opcua: %s", err)
+			continue
+		}
+		if value == nil {
+			continue
+		}
+
+		key := groupKey(mapping.MetricName, mapping.MetricTags)
+		group, ok := groups[key]
+		if !ok {
+			group = &metricGroup{name: mapping.MetricName, tags: mapping.MetricTags, fields: make(map[string]interface{})}
+			groups[key] = group
+		}
+		group.fields[mapping.Tag.FieldName] = value
+	}
+
+	for _, group := range groups {
+		acc.AddFields(group.name, group.fields, group.tags)
+	}
+
+	return nil
+}
+
+// metricGroup accumulates the fields for one (metric name, tag set)
+// combination, so nodes that share a metric name but carry different tags
+// (a per-node default_tags override, or the circuit breaker's state tag)
+// are emitted as separate metrics instead of being merged under whichever
+// node happened to be processed first.
+type metricGroup struct {
+	name   string
+	tags   map[string]string
+	fields map[string]interface{}
+}
+
+// groupKey builds a stable map key for a metric name and its fully-resolved
+// tag set.
+func groupKey(metricName string, tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(metricName)
+	for _, k := range keys {
+		b.WriteByte('\x00')
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(tags[k])
+	}
+	return b.String()
+}
+
+func (o *OpcUA) Stop() {
+	if o.client == nil {
+		return
+	}
+	if err := o.client.Stop(); err != nil {
+		o.Log.Errorf("closing session: %s", err)
+	}
+}
+
+func init() {
+	inputs.Add("opcua", func() telegraf.Input {
+		return &OpcUA{
+			ReadClientConfig: ReadClientConfig{},
+		}
+	})
+}
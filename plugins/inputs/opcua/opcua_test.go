@@ -80,8 +80,10 @@ func testReadClient(args TestReadClientArgs) {
 	require.NoError(args.t, opcuaInput.Gather(&testutil.Accumulator{}), "Gather")
 
 	if args.validateLastReceivedData {
-		for i, v := range opcuaInput.client.LastReceivedData {
-			require.Equal(args.t, args.testOPCTags[i].Want, v.Value)
+		for i := range args.testOPCTags {
+			v, err := opcuaInput.client.ReadValue(i)
+			require.NoError(args.t, err)
+			require.Equal(args.t, args.testOPCTags[i].Want, v)
 		}
 	}
 }
@@ -191,6 +193,74 @@ func TestReadClientIntegrationWithAuth(t *testing.T) {
 	})
 }
 
+func TestReadClientIntegrationWithIssuedToken(t *testing.T) {
+	testReadClient(TestReadClientArgs{
+		t:                   t,
+		containerEntrypoint: []string{"/opt/open62541/build/bin/examples/access_control_server_issued_token"},
+		testOPCTags: []OPCTags{
+			{"ProductName", "0", "i", "2261", "open62541 OPC UA Server"},
+			{"ProductUri", "0", "i", "2262", "http://open62541.org"},
+			{"ManufacturerName", "0", "i", "2263", "open62541"},
+		},
+		readConfig: ReadClientConfig{
+			InputClientConfig: input.InputClientConfig{
+				OpcUAClientConfig: opcua.OpcUAClientConfig{
+					SecurityPolicy: "None",
+					SecurityMode:   "None",
+					AuthMethod:     "IssuedToken",
+					IssuedToken:    &opcua.IssuedTokenConfig{Token: "test-access-token"},
+					ConnectTimeout: config.Duration(10 * time.Second),
+					RequestTimeout: config.Duration(1 * time.Second),
+					Workarounds:    opcua.OpcUAWorkarounds{},
+				},
+				MetricName: "testing",
+				RootNodes:  make([]input.NodeSettings, 0),
+				Groups:     make([]input.NodeGroupSettings, 0),
+			},
+		},
+		validateLastReceivedData: true,
+	})
+}
+
+func TestReadClientConfigIssuedToken(t *testing.T) {
+	toml := `
+[[inputs.opcua]]
+name = "localhost"
+endpoint = "opc.tcp://localhost:4840"
+auth_method = "IssuedToken"
+
+[inputs.opcua.issued_token]
+token_file = "/etc/telegraf/opcua_token"
+
+[inputs.opcua.issued_token.oidc]
+token_url = "https://auth.example.com/oauth2/token"
+client_id = "telegraf"
+client_secret = "shh"
+scopes = ["opcua"]
+audience = "urn:opcua-server"
+`
+
+	c := config.NewConfig()
+	err := c.LoadConfigData([]byte(toml))
+	require.NoError(t, err)
+
+	require.Len(t, c.Inputs, 1)
+
+	o, ok := c.Inputs[0].Input.(*OpcUA)
+	require.True(t, ok)
+
+	require.Equal(t, "IssuedToken", o.ReadClientConfig.AuthMethod)
+	require.NotNil(t, o.ReadClientConfig.IssuedToken)
+	require.Equal(t, "/etc/telegraf/opcua_token", o.ReadClientConfig.IssuedToken.TokenFile)
+	require.Equal(t, &opcua.OIDCClientCredentialsConfig{
+		TokenURL:     "https://auth.example.com/oauth2/token",
+		ClientID:     "telegraf",
+		ClientSecret: "shh",
+		Scopes:       []string{"opcua"},
+		Audience:     "urn:opcua-server",
+	}, o.ReadClientConfig.IssuedToken.OIDC)
+}
+
 func TestReadClientConfig(t *testing.T) {
 	toml := `
 [[inputs.opcua]]
@@ -327,30 +397,31 @@ use_unregistered_reads = true
 	)
 	err = o.Init()
 	require.NoError(t, err)
-	require.Len(t, o.client.NodeMetricMapping, 5, "incorrect number of nodes")
+	mappings := o.client.Metrics()
+	require.Len(t, mappings, 5, "incorrect number of nodes")
 	require.EqualValues(
 		t,
-		o.client.NodeMetricMapping[0].MetricTags,
+		mappings[0].MetricTags,
 		map[string]string{"tag0": "val0"},
 	)
 	require.EqualValues(
 		t,
-		o.client.NodeMetricMapping[1].MetricTags,
+		mappings[1].MetricTags,
 		map[string]string{"tag6": "val6"},
 	)
 	require.EqualValues(
 		t,
-		o.client.NodeMetricMapping[2].MetricTags,
+		mappings[2].MetricTags,
 		map[string]string{"tag1": "val1", "tag2": "val2", "tag3": "val3"},
 	)
 	require.EqualValues(
 		t,
-		o.client.NodeMetricMapping[3].MetricTags,
+		mappings[3].MetricTags,
 		map[string]string{"tag1": "override", "tag2": "val2"},
 	)
 	require.EqualValues(
 		t,
-		o.client.NodeMetricMapping[4].MetricTags,
+		mappings[4].MetricTags,
 		map[string]string{"tag1": "val1", "tag2": "val2"},
 	)
 }
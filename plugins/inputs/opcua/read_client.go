@@ -0,0 +1,385 @@
+package opcua
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gopcua/opcua/ua"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/opcua"
+	"github.com/influxdata/telegraf/plugins/common/opcua/input"
+)
+
+// ReadClientWorkarounds holds knobs that change how reads are issued rather
+// than how the session itself is established.
+type ReadClientWorkarounds struct {
+	// UseUnregisteredReads skips the RegisterNodes call and issues a plain
+	// Read against the raw NodeIds on every Gather. Some servers don't
+	// implement node registration correctly, so this trades a bit of
+	// throughput for compatibility.
+	UseUnregisteredReads bool `toml:"use_unregistered_reads"`
+}
+
+// ReadClientConfig is the full configuration for the opcua input: the
+// shared node/connection settings, read-specific workarounds and, when
+// present, the subscription parameters used by nodes that opt into
+// subscription based updates instead of polling.
+type ReadClientConfig struct {
+	input.InputClientConfig
+	ReadClientWorkarounds ReadClientWorkarounds     `toml:"request_workarounds"`
+	Subscription          *opcua.SubscriptionConfig `toml:"subscription"`
+}
+
+// NodeValue is the last value (or error) observed for a node, regardless of
+// whether it was obtained via a poll Read or a subscription notification.
+type NodeValue struct {
+	Value   interface{}
+	Quality ua.StatusCode
+}
+
+// ReadClient drives a single OPC UA session on behalf of the opcua input: it
+// resolves the configured nodes into NodeMetricMapping entries and refreshes
+// their values on every Gather.
+type ReadClient struct {
+	*opcua.OpcUAClient
+
+	Config ReadClientConfig
+	Log    telegraf.Logger
+
+	NodeMetricMapping []input.NodeMetricMapping
+	LastReceivedData  []NodeValue
+
+	registeredIDs []*ua.NodeID
+
+	// pollIndices holds the NodeMetricMapping indices that are read on every
+	// Gather; subscribeIndices holds the ones pushed via subscription.
+	pollIndices      []int
+	subscribeIndices []int
+
+	sub         *subscriptionState
+	discoverers []*discoverer
+
+	sessionCancel context.CancelFunc
+
+	// mappingMu guards NodeMetricMapping/LastReceivedData/pollIndices
+	// against concurrent appends from a discovery refresh goroutine.
+	mappingMu sync.RWMutex
+}
+
+func NewReadClient(cfg ReadClientConfig, log telegraf.Logger) (*ReadClient, error) {
+	base, err := cfg.OpcUAClientConfig.CreateClient(log)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReadClient{
+		OpcUAClient: base,
+		Config:      cfg,
+		Log:         log,
+	}, nil
+}
+
+// Init resolves every configured root node and group node into a flat
+// NodeMetricMapping table, preserving declaration order so indices stay
+// stable across Gather calls.
+func (r *ReadClient) Init() error {
+	r.NodeMetricMapping = r.NodeMetricMapping[:0]
+
+	for _, tag := range r.Config.RootNodes {
+		mapping, err := input.NewNodeMetricMapping(r.Config.MetricName, tag, nil)
+		if err != nil {
+			return err
+		}
+		if tag.Subscribe != nil {
+			mapping.Subscribe = *tag.Subscribe
+		}
+		r.NodeMetricMapping = append(r.NodeMetricMapping, mapping)
+	}
+
+	for _, group := range r.Config.Groups {
+		metricName := group.MetricName
+		if metricName == "" {
+			metricName = r.Config.MetricName
+		}
+
+		groupTags := make(map[string]string, len(group.TagsSlice)+len(group.DefaultTags))
+		for _, pair := range group.TagsSlice {
+			groupTags[pair[0]] = pair[1]
+		}
+		for k, v := range group.DefaultTags {
+			groupTags[k] = v
+		}
+
+		for _, node := range group.Nodes {
+			if node.Namespace == "" {
+				node.Namespace = group.Namespace
+			}
+			if node.IdentifierType == "" {
+				node.IdentifierType = group.IdentifierType
+			}
+
+			mapping, err := input.NewNodeMetricMapping(metricName, node, groupTags)
+			if err != nil {
+				return err
+			}
+			mapping.Subscribe = group.ShouldSubscribe(node)
+			mapping.DeadbandType = group.DeadbandType
+			mapping.DeadbandValue = group.DeadbandValue
+			r.NodeMetricMapping = append(r.NodeMetricMapping, mapping)
+		}
+	}
+
+	r.LastReceivedData = make([]NodeValue, len(r.NodeMetricMapping))
+
+	r.pollIndices = r.pollIndices[:0]
+	r.subscribeIndices = r.subscribeIndices[:0]
+	for i, m := range r.NodeMetricMapping {
+		if m.Subscribe && r.Config.Subscription != nil {
+			r.subscribeIndices = append(r.subscribeIndices, i)
+			continue
+		}
+		r.pollIndices = append(r.pollIndices, i)
+	}
+
+	r.discoverers = r.discoverers[:0]
+	for _, d := range r.Config.Discovery {
+		disc, err := newDiscoverer(d)
+		if err != nil {
+			return err
+		}
+		r.discoverers = append(r.discoverers, disc)
+	}
+
+	return nil
+}
+
+// nodeID parses the gopcua NodeID for the given resolved node.
+func (r *ReadClient) nodeID(m input.NodeMetricMapping) (*ua.NodeID, error) {
+	id, err := ua.ParseNodeID(fmt.Sprintf("ns=%s;%s=%s", m.Tag.Namespace, m.Tag.IdentifierType, m.Tag.Identifier))
+	if err != nil {
+		return nil, fmt.Errorf("parsing node id for %q: %w", m.Tag.FieldName, err)
+	}
+	return id, nil
+}
+
+// Connect opens the underlying session, starts subscriptions for any nodes
+// that opted into them and, unless disabled by a workaround, registers the
+// remaining polled nodes for faster repeated reads. On any failure after the
+// session is established, it cancels that session before returning so the
+// caller's retry on the next Gather starts clean instead of leaving the
+// failed attempt's discovery-refresh goroutines and subscription running
+// alongside whatever the retry spins up.
+func (r *ReadClient) Connect() (err error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		if err != nil {
+			cancel()
+		}
+	}()
+
+	if err := recoverCall("", func() error { return r.OpcUAClient.Connect(ctx) }); err != nil {
+		return err
+	}
+	r.sessionCancel = cancel
+
+	if len(r.discoverers) > 0 {
+		if err := r.runDiscoverers(ctx); err != nil {
+			return fmt.Errorf("running discovery: %w", err)
+		}
+		r.startDiscoveryRefresh(ctx)
+	}
+
+	if len(r.subscribeIndices) > 0 {
+		sub, err := r.startSubscription(ctx)
+		if err != nil {
+			return fmt.Errorf("starting subscription: %w", err)
+		}
+		r.sub = sub
+	}
+
+	if r.Config.ReadClientWorkarounds.UseUnregisteredReads || len(r.pollIndices) == 0 {
+		return nil
+	}
+
+	ids := make([]*ua.NodeID, 0, len(r.pollIndices))
+	for _, idx := range r.pollIndices {
+		id, err := r.nodeID(r.NodeMetricMapping[idx])
+		if err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	}
+
+	resp, err := r.Client.RegisterNodes(ctx, &ua.RegisterNodesRequest{NodesToRegister: ids})
+	if err != nil {
+		return fmt.Errorf("registering nodes: %w", err)
+	}
+
+	r.registeredIDs = resp.RegisteredNodeIDs
+
+	return nil
+}
+
+// read issues a single Read service call against the given ids and stores
+// the results, in order, into LastReceivedData at the given indices. Nodes
+// whose circuit breaker is open are skipped entirely rather than being
+// included in the Read request.
+func (r *ReadClient) read(ctx context.Context, indices []int, ids []*ua.NodeID) error {
+	req := &ua.ReadRequest{
+		MaxAge:             0,
+		TimestampsToReturn: ua.TimestampsToReturnBoth,
+	}
+
+	activeIndices := make([]int, 0, len(ids))
+	activeKeys := make([]string, 0, len(ids))
+	for i, id := range ids {
+		idx := indices[i]
+		key := id.String()
+
+		if r.Breaker != nil && !r.Breaker.Allow(key) {
+			r.LastReceivedData[idx] = NodeValue{Value: nil, Quality: ua.StatusBadCommunicationError}
+			r.NodeMetricMapping[idx].MetricTags["state"] = r.Breaker.State(key)
+			continue
+		}
+
+		req.NodesToRead = append(req.NodesToRead, &ua.ReadValueID{NodeID: id})
+		activeIndices = append(activeIndices, idx)
+		activeKeys = append(activeKeys, key)
+	}
+
+	if len(req.NodesToRead) == 0 {
+		return nil
+	}
+
+	var resp *ua.ReadResponse
+	err := recoverCall("", func() error {
+		var callErr error
+		resp, callErr = r.Client.ReadWithContext(ctx, req)
+		return callErr
+	})
+	if err != nil {
+		// Every key in activeKeys consumed an Allow() above (possibly a
+		// half-open probe); since no per-node result arrives when the
+		// batched call itself fails, they'd otherwise never record an
+		// outcome and a half-open breaker could wedge itself shut forever.
+		if r.Breaker != nil {
+			for i, key := range activeKeys {
+				r.recordNodeBreakerResult(activeIndices[i], key, ua.StatusBadCommunicationError)
+			}
+		}
+		return fmt.Errorf("reading nodes: %w", err)
+	}
+
+	for i, result := range resp.Results {
+		idx := activeIndices[i]
+
+		if r.Breaker != nil {
+			r.recordNodeBreakerResult(idx, activeKeys[i], result.Status)
+		}
+
+		if !r.Config.StatusCodeOK(result.Status) {
+			if reauthErr := r.OpcUAClient.ReauthenticateOnInvalidSession(ctx, result.Status); reauthErr != nil {
+				r.Log.Errorf("opcua: re-authenticating after %s: %s", result.Status, reauthErr)
+			}
+			r.LastReceivedData[idx] = NodeValue{Value: nil, Quality: result.Status}
+			continue
+		}
+		r.LastReceivedData[idx] = NodeValue{Value: result.Value.Value(), Quality: result.Status}
+	}
+
+	return nil
+}
+
+// recordNodeBreakerResult feeds a node's read status back into its circuit
+// breaker entry, tags its metric with the resulting state and logs any
+// transition.
+func (r *ReadClient) recordNodeBreakerResult(idx int, key string, status ua.StatusCode) {
+	before := r.Breaker.State(key)
+	r.Breaker.RecordResult(key, status)
+	after := r.Breaker.State(key)
+
+	r.NodeMetricMapping[idx].MetricTags["state"] = after
+	if before != after {
+		r.Log.Warnf("opcua: circuit breaker for node %q transitioned %s -> %s", key, before, after)
+	}
+}
+
+// Read refreshes LastReceivedData for every resolved node: polled nodes are
+// re-read here, using the registered ids when available, while subscribed
+// nodes are drained from the buffer of DataChangeNotifications accumulated
+// since the last Gather.
+func (r *ReadClient) Read() error {
+	r.mappingMu.RLock()
+	defer r.mappingMu.RUnlock()
+
+	if r.sub != nil {
+		r.sub.drainInto(r.LastReceivedData)
+	}
+
+	if len(r.pollIndices) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), durationOrDefault(r.Config.RequestTimeout))
+	defer cancel()
+
+	if len(r.registeredIDs) == len(r.pollIndices) {
+		return r.read(ctx, r.pollIndices, r.registeredIDs)
+	}
+
+	ids := make([]*ua.NodeID, 0, len(r.pollIndices))
+	for _, idx := range r.pollIndices {
+		id, err := r.nodeID(r.NodeMetricMapping[idx])
+		if err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	}
+
+	return r.read(ctx, r.pollIndices, ids)
+}
+
+// Stop cancels any running subscription and discovery refresh goroutines and
+// closes the session.
+func (r *ReadClient) Stop() error {
+	r.sub.stop()
+	if r.sessionCancel != nil {
+		r.sessionCancel()
+	}
+	return r.OpcUAClient.Disconnect(context.Background())
+}
+
+// Metrics returns a snapshot of the resolved node/tag configuration. Use
+// ReadValue to look up the last value received for a given index.
+func (r *ReadClient) Metrics() []input.NodeMetricMapping {
+	r.mappingMu.RLock()
+	defer r.mappingMu.RUnlock()
+
+	out := make([]input.NodeMetricMapping, len(r.NodeMetricMapping))
+	copy(out, r.NodeMetricMapping)
+	return out
+}
+
+// ReadValue returns the last value received for the node at idx, as
+// resolved by the most recent Read call.
+func (r *ReadClient) ReadValue(idx int) (interface{}, error) {
+	r.mappingMu.RLock()
+	defer r.mappingMu.RUnlock()
+
+	if idx < 0 || idx >= len(r.LastReceivedData) {
+		return nil, fmt.Errorf("node index %d out of range", idx)
+	}
+
+	return r.LastReceivedData[idx].Value, nil
+}
+
+func durationOrDefault(d config.Duration) time.Duration {
+	if d == 0 {
+		return 5 * time.Second
+	}
+	return time.Duration(d)
+}
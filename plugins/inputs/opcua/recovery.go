@@ -0,0 +1,81 @@
+package opcua
+
+import (
+	"fmt"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/opcua/input"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+// panicsRecovered counts panics this plugin has recovered from, across every
+// opcua input instance, surfaced as internal_opcua.panics_recovered_total.
+var panicsRecovered = selfstat.Register("opcua", "panics_recovered_total", map[string]string{})
+
+// NodeError wraps an error with the node it happened on, so logs and
+// returned errors can point at the offending NodeId.
+type NodeError struct {
+	NodeID string
+	Err    error
+}
+
+func (e *NodeError) Error() string {
+	return fmt.Sprintf("node %s: %s", e.NodeID, e.Err)
+}
+
+func (e *NodeError) Unwrap() error {
+	return e.Err
+}
+
+func nodeID(tag input.NodeSettings) string {
+	return fmt.Sprintf("ns=%s;%s=%s", tag.Namespace, tag.IdentifierType, tag.Identifier)
+}
+
+// recoverCall invokes fn, converting any panic it raises into an error
+// instead of letting it unwind past this call. This is what actually sits
+// between the opcua input and the gopcua library: the real risk of a panic
+// is in the library calls made from ReadClient.read, ReadClient.Connect and
+// ReadClient.browse, not in decoding an already-fetched value. When node is
+// non-empty the error is wrapped in a *NodeError so logs and callers can see
+// which node was involved; pass "" for calls that aren't scoped to a single
+// node (a batched Read, Connect).
+func recoverCall(node string, fn func() error) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			panicsRecovered.Incr(1)
+			cause := fmt.Errorf("panic in opcua client call: %v", p)
+			if node == "" {
+				err = cause
+				return
+			}
+			err = &NodeError{NodeID: node, Err: cause}
+		}
+	}()
+
+	return fn()
+}
+
+// readNodeValue reads a single node's value through client. ReadValue itself
+// is just a bounds check and a slice read of already-decoded data, so this
+// exists to satisfy the OpcUAInputClient contract generally, not because
+// it's expected to panic; the gopcua library calls that actually can panic
+// are wrapped individually with recoverCall.
+func readNodeValue(client input.OpcUAInputClient, idx int, mapping input.NodeMetricMapping) (interface{}, error) {
+	var value interface{}
+	err := recoverCall(nodeID(mapping.Tag), func() error {
+		v, err := client.ReadValue(idx)
+		value = v
+		return err
+	})
+	return value, err
+}
+
+// recoverGoroutine is deferred at the top of every long-running goroutine
+// the opcua input spawns (subscription delivery, discovery refresh) so a
+// panic there is recorded instead of crashing the process.
+func recoverGoroutine(log telegraf.Logger, context string) {
+	if p := recover(); p != nil {
+		panicsRecovered.Incr(1)
+		log.Errorf("opcua: recovered panic in %s: %v", context, p)
+	}
+}
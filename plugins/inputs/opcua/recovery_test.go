@@ -0,0 +1,139 @@
+package opcua
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/plugins/common/opcua/input"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestRecoverCallConvertsPanicToError(t *testing.T) {
+	err := recoverCall("", func() error {
+		panic("simulated gopcua library panic")
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "simulated gopcua library panic")
+
+	_, isNodeErr := err.(*NodeError)
+	require.False(t, isNodeErr, "no node context expected for an empty key")
+}
+
+func TestRecoverCallTagsPanicWithNode(t *testing.T) {
+	err := recoverCall("ns=2;i=42", func() error {
+		panic("simulated gopcua library panic")
+	})
+	require.Error(t, err)
+
+	var nodeErr *NodeError
+	require.ErrorAs(t, err, &nodeErr)
+	require.Equal(t, "ns=2;i=42", nodeErr.NodeID)
+}
+
+func TestRecoverCallPassesThroughOrdinaryError(t *testing.T) {
+	want := fmt.Errorf("ordinary failure")
+	err := recoverCall("", func() error { return want })
+	require.Equal(t, want, err)
+}
+
+// fakeInputClient is a minimal input.OpcUAInputClient whose ReadValue can be
+// made to panic for specific indices, so Gather's panic recovery can be
+// exercised without a real OPC UA session.
+type fakeInputClient struct {
+	mappings []input.NodeMetricMapping
+	values   []interface{}
+	panicAt  map[int]bool
+}
+
+func (f *fakeInputClient) Init() error    { return nil }
+func (f *fakeInputClient) Connect() error { return nil }
+func (f *fakeInputClient) Stop() error    { return nil }
+func (f *fakeInputClient) Read() error    { return nil }
+
+func (f *fakeInputClient) Metrics() []input.NodeMetricMapping {
+	return f.mappings
+}
+
+func (f *fakeInputClient) ReadValue(idx int) (interface{}, error) {
+	if f.panicAt[idx] {
+		panic(fmt.Sprintf("simulated failure reading index %d", idx))
+	}
+	return f.values[idx], nil
+}
+
+func TestGatherRecoversPanicFromSingleNode(t *testing.T) {
+	tests := []struct {
+		name        string
+		panicAt     map[int]bool
+		wantFields  map[string]interface{}
+		wantLogNode string
+	}{
+		{
+			name:    "no panics",
+			panicAt: map[int]bool{},
+			wantFields: map[string]interface{}{
+				"good1": int32(1),
+				"good2": int32(2),
+			},
+		},
+		{
+			name:    "last node panics",
+			panicAt: map[int]bool{1: true},
+			wantFields: map[string]interface{}{
+				"good1": int32(1),
+			},
+			wantLogNode: "ns=0;i=2",
+		},
+		{
+			name:       "every node panics",
+			panicAt:    map[int]bool{0: true, 1: true},
+			wantFields: map[string]interface{}{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mappings := []input.NodeMetricMapping{
+				{
+					Tag:        input.NodeSettings{FieldName: "good1", Namespace: "0", IdentifierType: "i", Identifier: "1"},
+					MetricName: "test",
+					MetricTags: map[string]string{},
+				},
+				{
+					Tag:        input.NodeSettings{FieldName: "good2", Namespace: "0", IdentifierType: "i", Identifier: "2"},
+					MetricName: "test",
+					MetricTags: map[string]string{},
+				},
+			}
+
+			client := &fakeInputClient{
+				mappings: mappings,
+				values:   []interface{}{int32(1), int32(2)},
+				panicAt:  tt.panicAt,
+			}
+
+			logger := &testutil.CaptureLogger{}
+			o := &OpcUA{
+				Log:       logger,
+				client:    client,
+				connected: true,
+			}
+
+			acc := &testutil.Accumulator{}
+			require.NoError(t, o.Gather(acc))
+
+			if len(tt.wantFields) == 0 {
+				require.Empty(t, acc.Metrics)
+			} else {
+				acc.AssertContainsFields(t, "test", tt.wantFields)
+			}
+
+			if tt.wantLogNode != "" {
+				require.True(t, strings.Contains(logger.String(), tt.wantLogNode), "expected log to contain %q, got: %s", tt.wantLogNode, logger.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package opcua
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gopcua/opcua/monitor"
+	"github.com/gopcua/opcua/ua"
+)
+
+// subscriptionState owns the CreateSubscription/CreateMonitoredItems session
+// for the nodes that opted into push updates, and buffers the
+// DataChangeNotifications it receives until the next Gather drains them.
+type subscriptionState struct {
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	pending map[int]NodeValue
+}
+
+// startSubscription creates a single OPC UA subscription covering every
+// node in r.subscribeIndices, using r.Config.Subscription for the
+// publishing/sampling interval and per-group deadband filters, and starts a
+// goroutine that buffers incoming DataChangeNotifications until Read drains
+// them.
+func (r *ReadClient) startSubscription(ctx context.Context) (*subscriptionState, error) {
+	nm, err := monitor.NewNodeMonitor(r.Client)
+	if err != nil {
+		return nil, fmt.Errorf("creating node monitor: %w", err)
+	}
+
+	// r.NodeMetricMapping can already be growing under a
+	// concurrent discovery refresh by the time this runs, so read them
+	// under the same lock runDiscoverers appends under.
+	r.mappingMu.RLock()
+	items := make([]*monitor.NodeMonitorRequest, 0, len(r.subscribeIndices))
+	for _, idx := range r.subscribeIndices {
+		m := r.NodeMetricMapping[idx]
+		id, err := r.nodeID(m)
+		if err != nil {
+			r.mappingMu.RUnlock()
+			return nil, err
+		}
+
+		req := monitor.Request(id, ua.AttributeIDValue)
+		req.MonitoringParameters.QueueSize = r.Config.Subscription.QueueSize
+		req.MonitoringParameters.DiscardOldest = r.Config.Subscription.DiscardOldest
+		req.MonitoringParameters.SamplingInterval = float64(time.Duration(r.Config.Subscription.SamplingInterval).Milliseconds())
+		if m.DeadbandType != "" {
+			req.MonitoringParameters.Filter = deadbandFilter(m.DeadbandType, m.DeadbandValue)
+		}
+		items = append(items, req)
+	}
+	r.mappingMu.RUnlock()
+
+	subCtx, cancel := context.WithCancel(ctx)
+
+	ch := make(chan *monitor.DataChangeMessage, len(items))
+	sub, err := nm.ChanSubscribe(
+		subCtx,
+		&opcuaSubscriptionParams{
+			Interval: time.Duration(r.Config.Subscription.PublishingInterval),
+		}.toMonitorParams(),
+		ch,
+		items...,
+	)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("creating subscription: %w", err)
+	}
+
+	state := &subscriptionState{
+		cancel:  cancel,
+		pending: make(map[int]NodeValue, len(r.subscribeIndices)),
+	}
+
+	go func() {
+		defer sub.Unsubscribe(subCtx)
+		defer recoverGoroutine(r.Log, "subscription delivery")
+		for {
+			select {
+			case <-subCtx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				r.onDataChange(state, msg)
+			}
+		}
+	}()
+
+	return state, nil
+}
+
+// onDataChange records the latest value for the node the notification
+// belongs to, identified by matching its NodeID against the subscribed
+// indices.
+func (r *ReadClient) onDataChange(state *subscriptionState, msg *monitor.DataChangeMessage) {
+	if msg.Error != nil {
+		r.Log.Errorf("opcua: subscription data change error: %s", msg.Error)
+		return
+	}
+
+	// Runs on the subscription delivery goroutine, concurrently with a
+	// discovery refresh appending to these same slices under mappingMu.
+	r.mappingMu.RLock()
+	defer r.mappingMu.RUnlock()
+
+	for _, idx := range r.subscribeIndices {
+		m := r.NodeMetricMapping[idx]
+		id, err := r.nodeID(m)
+		if err != nil || id.String() != msg.NodeID.String() {
+			continue
+		}
+
+		value := NodeValue{Value: msg.Value.Value(), Quality: msg.Status}
+
+		state.mu.Lock()
+		state.pending[idx] = value
+		state.mu.Unlock()
+		return
+	}
+}
+
+// drainInto copies every buffered notification into dst at its resolved
+// index and clears the buffer.
+func (s *subscriptionState) drainInto(dst []NodeValue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for idx, v := range s.pending {
+		dst[idx] = v
+		delete(s.pending, idx)
+	}
+}
+
+func (s *subscriptionState) stop() {
+	if s == nil {
+		return
+	}
+	s.cancel()
+}
+
+// deadbandFilter builds the MonitoringFilter used to suppress updates that
+// don't change by at least the configured amount.
+func deadbandFilter(deadbandType string, value float64) *ua.DataChangeFilter {
+	var dt uint32
+	switch deadbandType {
+	case "absolute":
+		dt = uint32(ua.DeadbandTypeAbsolute)
+	case "percent":
+		dt = uint32(ua.DeadbandTypePercent)
+	default:
+		dt = uint32(ua.DeadbandTypeNone)
+	}
+
+	return &ua.DataChangeFilter{
+		Trigger:       ua.DataChangeTriggerStatusValue,
+		DeadbandType:  dt,
+		DeadbandValue: value,
+	}
+}
+
+// opcuaSubscriptionParams is a thin wrapper translating our telegraf-facing
+// SubscriptionConfig into the gopcua monitor package's own parameter type.
+type opcuaSubscriptionParams struct {
+	Interval time.Duration
+}
+
+func (p opcuaSubscriptionParams) toMonitorParams() *monitor.SubscriptionParameters {
+	return &monitor.SubscriptionParameters{Interval: p.Interval}
+}